@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"note2anki/flashcard"
+)
+
+// openCollection builds a collection.anki2 for cards and returns an open
+// handle to it, removing the underlying temp file on test cleanup.
+func openCollection(t *testing.T, cards []flashcard.Flashcard) *sql.DB {
+	t.Helper()
+
+	dbPath, err := buildCollectionDB(cards)
+	if err != nil {
+		t.Fatalf("buildCollectionDB: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(dbPath) })
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("open collection db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestBuildCollectionDBNewCardIsUnseen(t *testing.T) {
+	db := openCollection(t, []flashcard.Flashcard{{Front: "Q", Back: "A"}})
+
+	var cardType, queue int
+	if err := db.QueryRow(`SELECT type, queue FROM cards`).Scan(&cardType, &queue); err != nil {
+		t.Fatalf("query card: %v", err)
+	}
+	if cardType != 0 || queue != 0 {
+		t.Fatalf("got type=%d queue=%d, want type=0 queue=0 for a never-reviewed card", cardType, queue)
+	}
+}
+
+func TestBuildCollectionDBReviewedCardSurvivesAsReviewCard(t *testing.T) {
+	card := flashcard.Flashcard{
+		Front:    "Q",
+		Back:     "A",
+		Reps:     5,
+		Interval: 30,
+		Ease:     2.5,
+		Due:      time.Now().AddDate(0, 0, 10),
+	}
+	db := openCollection(t, []flashcard.Flashcard{card})
+
+	var crt int64
+	if err := db.QueryRow(`SELECT crt FROM col`).Scan(&crt); err != nil {
+		t.Fatalf("query col: %v", err)
+	}
+
+	var cardType, queue int
+	var due, ivl, reps int64
+	if err := db.QueryRow(`SELECT type, queue, due, ivl, reps FROM cards`).Scan(&cardType, &queue, &due, &ivl, &reps); err != nil {
+		t.Fatalf("query card: %v", err)
+	}
+
+	if cardType != 2 || queue != 2 {
+		t.Fatalf("got type=%d queue=%d, want type=2 queue=2 for a reviewed card", cardType, queue)
+	}
+	if ivl != int64(card.Interval) || reps != int64(card.Reps) {
+		t.Fatalf("got ivl=%d reps=%d, want ivl=%d reps=%d", ivl, reps, card.Interval, card.Reps)
+	}
+
+	wantDue := int64(card.Due.Sub(time.Unix(crt, 0)).Hours() / 24)
+	if due != wantDue {
+		t.Fatalf("got due=%d days since crt, want %d (Due measured from col.crt, not an absolute epoch day)", due, wantDue)
+	}
+}
+
+func TestBuildCollectionDBNotetypeHasRenderableTemplate(t *testing.T) {
+	db := openCollection(t, []flashcard.Flashcard{{Front: "Q", Back: "A"}})
+
+	var modelsJSON string
+	if err := db.QueryRow(`SELECT models FROM col`).Scan(&modelsJSON); err != nil {
+		t.Fatalf("query col: %v", err)
+	}
+
+	var models map[string]struct {
+		Flds []struct {
+			Name string `json:"name"`
+			Ord  int    `json:"ord"`
+		} `json:"flds"`
+		Tmpls []struct {
+			Qfmt string `json:"qfmt"`
+			Afmt string `json:"afmt"`
+		} `json:"tmpls"`
+	}
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		t.Fatalf("models is not valid JSON: %v", err)
+	}
+
+	for _, model := range models {
+		if len(model.Tmpls) == 0 || model.Tmpls[0].Qfmt == "" || model.Tmpls[0].Afmt == "" {
+			t.Fatalf("notetype template is missing qfmt/afmt: %+v", model.Tmpls)
+		}
+		if len(model.Flds) != 2 || model.Flds[0].Name != "Front" || model.Flds[1].Name != "Back" {
+			t.Fatalf("notetype fields not as expected: %+v", model.Flds)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// LLMBackend converts parsed document content into flashcards. Each
+// provider (Anthropic, OpenAI, Ollama, LocalAI, ...) owns its own
+// request/response shape behind this interface.
+type LLMBackend interface {
+	GenerateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error)
+}
+
+// NewLLMBackend selects and constructs the LLMBackend named by
+// config.Provider.
+func NewLLMBackend(config Config) (LLMBackend, error) {
+	switch config.Provider {
+	case "", "anthropic":
+		if config.BaseURL == "" {
+			config.BaseURL = "https://api.anthropic.com/v1"
+		}
+		return NewAnthropicBackend(config), nil
+	case "openai":
+		if config.BaseURL == "" {
+			config.BaseURL = "https://api.openai.com/v1"
+		}
+		return NewOpenAIBackend(config), nil
+	case "localai":
+		if config.BaseURL == "" {
+			config.BaseURL = "http://localhost:8080/v1"
+		}
+		return NewLocalAIBackend(config), nil
+	case "ollama":
+		if config.BaseURL == "" {
+			config.BaseURL = "http://localhost:11434"
+		}
+		return &OllamaBackend{config: config, httpClient: &http.Client{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %q", config.Provider)
+	}
+}
+
+// flashcardSystemPrompt is the instruction set shared by every backend:
+// it is the prompt, not the transport, that tells the model how to turn
+// notes into atomic, gradable flashcards.
+func flashcardSystemPrompt(config Config) string {
+	if config.SystemPrompt != "" {
+		return config.SystemPrompt
+	}
+
+	return `You are an expert educator creating Anki flashcards.
+	Follow these principles:
+	1. Create atomic cards (one concept per card)
+	2. Make questions clear and unambiguous
+	3. Keep answers concise but complete
+	4. Focus on key concepts, definitions, formulas, and relationships
+	5. Use active recall principles
+
+	CRITICAL: Respond ONLY with a valid JSON array. Do not include any explanatory text, introductions, or conclusions.
+	Output format: JSON array of objects with "front" (question) and "back" (answer) fields.
+	Generate comprehensive flashcards covering all important information.`
+}
+
+func flashcardUserPrompt(content, subject string) string {
+	return fmt.Sprintf(`Convert the following %s notes into Anki flashcards:
+
+%s
+
+Create flashcards that cover all key concepts, ensuring each card tests a single piece of knowledge.
+Output as a JSON array.`, subject, content)
+}
+
+// parseFlashcardResponse extracts and validates the JSON array a backend's
+// raw text response is expected to contain. In strict mode, extraction is
+// a schema-validating passthrough rather than best-effort string trimming
+// (see strictJSONPassthrough).
+func parseFlashcardResponse(responseContent string, strict bool) ([]Flashcard, error) {
+	extract := extractJSON
+	if strict {
+		extract = strictJSONPassthrough
+	}
+
+	jsonContent, err := extract(responseContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract JSON from response: %w\nActual response: %s", err, responseContent)
+	}
+
+	var flashcards []Flashcard
+	if err := json.Unmarshal([]byte(jsonContent), &flashcards); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted JSON: %w\nExtracted JSON: %s", err, jsonContent)
+	}
+
+	return flashcards, nil
+}
+
+// openAICompatClient implements the shared OpenAI chat-completions wire
+// format used by Anthropic's, OpenAI's, and LocalAI's APIs. Each provider
+// embeds it and exposes its own named type so they can diverge later
+// (e.g. provider-specific headers or prompt quirks) without touching the
+// others.
+type openAICompatClient struct {
+	client *openai.Client
+	config Config
+
+	// useJSONSchema enables response_format=json_schema when the config
+	// asks for -strict-json. Only real OpenAI endpoints support this;
+	// Anthropic's OpenAI-compatible shim does not, so AnthropicBackend
+	// leaves it false.
+	useJSONSchema bool
+	enforcer      SchemaEnforcer
+}
+
+func newOpenAICompatClient(config Config, useJSONSchema bool) openAICompatClient {
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	clientConfig.BaseURL = config.BaseURL
+	return openAICompatClient{
+		client:        openai.NewClientWithConfig(clientConfig),
+		config:        config,
+		useJSONSchema: useJSONSchema,
+	}
+}
+
+// generateFlashcards converts text content to flashcards via a
+// chat-completions call.
+func (c *openAICompatClient) generateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	req := openai.ChatCompletionRequest{
+		Model: c.config.Model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: flashcardSystemPrompt(c.config),
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: flashcardUserPrompt(content, subject),
+			},
+		},
+		MaxTokens:   c.config.MaxTokens,
+		Temperature: c.config.Temperature,
+	}
+
+	if c.config.StrictJSON && c.useJSONSchema {
+		req.ResponseFormat = c.enforcer.OpenAIResponseFormat()
+	}
+
+	resp, err := c.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("LLM request failed: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no response from LLM")
+	}
+
+	return parseFlashcardResponse(resp.Choices[0].Message.Content, c.config.StrictJSON)
+}
+
+// AnthropicBackend talks to the Anthropic API through its
+// OpenAI-compatible endpoint.
+type AnthropicBackend struct{ openAICompatClient }
+
+// NewAnthropicBackend creates a new Anthropic backend.
+func NewAnthropicBackend(config Config) *AnthropicBackend {
+	return &AnthropicBackend{newOpenAICompatClient(config, false)}
+}
+
+// GenerateFlashcards converts text content to flashcards.
+func (b *AnthropicBackend) GenerateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	return b.generateFlashcards(ctx, content, subject)
+}
+
+// OpenAIBackend talks to the OpenAI API directly.
+type OpenAIBackend struct{ openAICompatClient }
+
+// NewOpenAIBackend creates a new OpenAI backend.
+func NewOpenAIBackend(config Config) *OpenAIBackend {
+	return &OpenAIBackend{newOpenAICompatClient(config, true)}
+}
+
+// GenerateFlashcards converts text content to flashcards.
+func (b *OpenAIBackend) GenerateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	return b.generateFlashcards(ctx, content, subject)
+}
+
+// LocalAIBackend talks to a self-hosted LocalAI instance via its
+// OpenAI-compatible /v1/chat/completions endpoint. Unlike OpenAIBackend
+// it supports GBNF grammar-constrained decoding (llama.cpp's mechanism),
+// which go-openai's request struct has no field for, so -strict-json
+// routes it through a raw HTTP call instead of the shared openai.Client.
+type LocalAIBackend struct {
+	openAICompatClient
+	httpClient *http.Client
+	enforcer   SchemaEnforcer
+}
+
+// NewLocalAIBackend creates a new LocalAI backend.
+func NewLocalAIBackend(config Config) *LocalAIBackend {
+	return &LocalAIBackend{
+		openAICompatClient: newOpenAICompatClient(config, false),
+		httpClient:         &http.Client{},
+	}
+}
+
+// GenerateFlashcards converts text content to flashcards, using
+// grammar-constrained decoding when -strict-json is set.
+func (b *LocalAIBackend) GenerateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	if b.config.StrictJSON {
+		return b.generateFlashcardsWithGrammar(ctx, content, subject)
+	}
+	return b.generateFlashcards(ctx, content, subject)
+}
+
+// localAIGrammarRequest mirrors the OpenAI-compatible chat-completions
+// body with LocalAI's extra "grammar" field, which go-openai's
+// ChatCompletionRequest has no slot for.
+type localAIGrammarRequest struct {
+	Model       string                         `json:"model"`
+	Messages    []openai.ChatCompletionMessage `json:"messages"`
+	MaxTokens   int                            `json:"max_tokens,omitempty"`
+	Temperature float32                        `json:"temperature,omitempty"`
+	Grammar     string                         `json:"grammar"`
+}
+
+type localAIGrammarResponse struct {
+	Choices []struct {
+		Message openai.ChatCompletionMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (b *LocalAIBackend) generateFlashcardsWithGrammar(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	reqBody, err := json.Marshal(localAIGrammarRequest{
+		Model: b.config.Model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: flashcardSystemPrompt(b.config)},
+			{Role: openai.ChatMessageRoleUser, Content: flashcardUserPrompt(content, subject)},
+		},
+		MaxTokens:   b.config.MaxTokens,
+		Temperature: b.config.Temperature,
+		Grammar:     b.enforcer.Grammar(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode LocalAI request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(b.config.BaseURL, "/")+"/chat/completions", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build LocalAI request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if b.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+b.config.APIKey)
+	}
+
+	resp, err := b.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("LocalAI request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &httpStatusError{
+			statusCode: resp.StatusCode,
+			err:        fmt.Errorf("LocalAI returned status %d: %s", resp.StatusCode, string(body)),
+		}
+	}
+
+	var parsed localAIGrammarResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode LocalAI response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("no response from LLM")
+	}
+
+	// The grammar already guarantees well-formed JSON, but we still run
+	// it through the strict schema validator rather than trusting it
+	// blindly: a grammar can constrain syntax without constraining which
+	// fields are present.
+	return parseFlashcardResponse(parsed.Choices[0].Message.Content, true)
+}
+
+// OllamaBackend talks to a local Ollama server's native /api/generate
+// endpoint, which streams newline-delimited JSON rather than returning a
+// single chat-completions style response.
+type OllamaBackend struct {
+	config     Config
+	httpClient *http.Client
+}
+
+// ollamaGenerateRequest is the request body for POST /api/generate.
+type ollamaGenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	Format string `json:"format,omitempty"` // "json" constrains token-level output to valid JSON
+}
+
+// ollamaGenerateChunk is one line of the streaming NDJSON response body.
+type ollamaGenerateChunk struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+}
+
+// GenerateFlashcards converts text content to flashcards by streaming a
+// completion from Ollama and reassembling the chunks into one response.
+func (b *OllamaBackend) GenerateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	prompt := flashcardSystemPrompt(b.config) + "\n\n" + flashcardUserPrompt(content, subject)
+
+	var format string
+	if b.config.StrictJSON {
+		// Ollama's "json" format mode only constrains token-level syntax,
+		// not field names, so the schema still has to be spelled out in
+		// the prompt itself.
+		prompt += schemaPromptHint
+		format = "json"
+	}
+
+	reqBody, err := json.Marshal(ollamaGenerateRequest{
+		Model:  b.config.Model,
+		Prompt: prompt,
+		Stream: true,
+		Format: format,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Ollama request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(b.config.BaseURL, "/")+"/api/generate", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &httpStatusError{
+			statusCode: resp.StatusCode,
+			err:        fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, string(body)),
+		}
+	}
+
+	var full strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for decoder.More() {
+		var chunk ollamaGenerateChunk
+		if err := decoder.Decode(&chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode Ollama response chunk: %w", err)
+		}
+		full.WriteString(chunk.Response)
+		if chunk.Done {
+			break
+		}
+	}
+
+	return parseFlashcardResponse(full.String(), b.config.StrictJSON)
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"note2anki/parser"
+)
+
+// approxTokens estimates a token count for chunk budgeting using the
+// common ~4-characters-per-token heuristic. It doesn't need to be exact,
+// only consistent enough to keep chunks under a backend's context window.
+func approxTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+var paragraphSplit = regexp.MustCompile(`\n{2,}`)
+
+// chunkText splits content into overlapping chunks sized to a token
+// budget. Content is first divided on the parser.SectionMarker
+// boundaries parsers emit (PDF page breaks, Markdown headings, EPUB/PPTX
+// items); a section that still exceeds chunkTokens on its own falls back
+// to a paragraph split. Each chunk after the first is seeded with
+// roughly overlapTokens worth of trailing text from the previous one, so
+// context isn't lost at a chunk boundary.
+func chunkText(content string, chunkTokens, overlapTokens int) []string {
+	if chunkTokens <= 0 {
+		chunkTokens = defaultChunkTokens
+	}
+
+	var units []string
+	for _, section := range strings.Split(content, parser.SectionMarker) {
+		section = strings.TrimSpace(section)
+		if section == "" {
+			continue
+		}
+		if approxTokens(section) > chunkTokens {
+			for _, para := range paragraphSplit.Split(section, -1) {
+				if para = strings.TrimSpace(para); para != "" {
+					units = append(units, para)
+				}
+			}
+		} else {
+			units = append(units, section)
+		}
+	}
+
+	if len(units) == 0 {
+		return nil
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+
+	flush := func() {
+		if trimmed := strings.TrimSpace(current.String()); trimmed != "" {
+			chunks = append(chunks, trimmed)
+		}
+	}
+
+	for _, unit := range units {
+		unitTokens := approxTokens(unit)
+
+		if currentTokens > 0 && currentTokens+unitTokens > chunkTokens {
+			flush()
+			overlap := trailingTokens(current.String(), overlapTokens)
+			current.Reset()
+			currentTokens = 0
+			if overlap != "" {
+				current.WriteString(overlap)
+				current.WriteString("\n\n")
+				currentTokens = approxTokens(overlap)
+			}
+		}
+
+		current.WriteString(unit)
+		current.WriteString("\n\n")
+		currentTokens += unitTokens
+	}
+	flush()
+
+	return chunks
+}
+
+// trailingTokens returns roughly the trailing overlapTokens worth of s,
+// cut on a paragraph boundary where possible so the overlap carried into
+// the next chunk reads naturally instead of starting mid-sentence.
+func trailingTokens(s string, overlapTokens int) string {
+	if overlapTokens <= 0 {
+		return ""
+	}
+
+	targetChars := overlapTokens * 4
+	if targetChars >= len(s) {
+		return strings.TrimSpace(s)
+	}
+
+	tail := s[len(s)-targetChars:]
+	if idx := strings.Index(tail, "\n\n"); idx != -1 {
+		tail = tail[idx+2:]
+	}
+
+	return strings.TrimSpace(tail)
+}
+
+// chunkResult pairs a chunk's output flashcards with its original index,
+// so results can be reassembled in document order even though they were
+// generated out of order by the worker pool.
+type chunkResult struct {
+	index      int
+	flashcards []Flashcard
+	err        error
+}
+
+// processChunks dispatches chunks to llm concurrently across a worker
+// pool of the given size, retrying transient errors with exponential
+// backoff, and merges the results back in chunk order. ctx cancellation
+// (e.g. Ctrl-C) aborts any requests still in flight.
+func processChunks(ctx context.Context, llm LLMBackend, chunks []string, subject string, workers int) ([]Flashcard, error) {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	jobs := make(chan int)
+	results := make([]chunkResult, len(chunks))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				cards, err := generateWithRetry(ctx, llm, chunks[i], subject)
+				results[i] = chunkResult{index: i, flashcards: cards, err: err}
+			}
+		}()
+	}
+
+	for i := range chunks {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			close(jobs)
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var merged []Flashcard
+	for _, result := range results {
+		if result.err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", result.index+1, result.err)
+		}
+		merged = append(merged, result.flashcards...)
+	}
+
+	return merged, nil
+}
+
+// processChunksStream behaves like processChunks but calls onChunk as
+// soon as each chunk's flashcards are ready instead of waiting for the
+// whole document, so a caller like the /v1/convert/stream SSE handler
+// can emit cards incrementally. Results arrive in completion order, not
+// necessarily chunk order. onChunk is called from whichever worker
+// goroutine finished, serialized by an internal lock, so callers don't
+// need their own synchronization.
+func processChunksStream(ctx context.Context, llm LLMBackend, chunks []string, subject string, workers int, onChunk func([]Flashcard)) error {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	jobs := make(chan string)
+	errCh := make(chan error, 1)
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for content := range jobs {
+				cards, err := generateWithRetry(ctx, llm, content, subject)
+				if err != nil {
+					select {
+					case errCh <- fmt.Errorf("chunk processing failed: %w", err):
+					default:
+					}
+					continue
+				}
+
+				mu.Lock()
+				onChunk(cards)
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, c := range chunks {
+		select {
+		case jobs <- c:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+// Retry tuning for transient (429/5xx) backend errors: a handful of
+// attempts with exponential backoff is enough to ride out rate limits
+// and brief outages without stalling the whole pipeline on one chunk.
+const (
+	maxRetries     = 4
+	retryBaseDelay = 500 * time.Millisecond
+)
+
+// generateWithRetry calls llm.GenerateFlashcards, retrying with
+// exponential backoff when the error looks transient (HTTP 429 or 5xx).
+// Any other error, or ctx cancellation, returns immediately.
+func generateWithRetry(ctx context.Context, llm LLMBackend, content, subject string) ([]Flashcard, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := retryBaseDelay * time.Duration(math.Pow(2, float64(attempt-1)))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		cards, err := llm.GenerateFlashcards(ctx, content, subject)
+		if err == nil {
+			return cards, nil
+		}
+		lastErr = err
+
+		if !isRetryableError(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", maxRetries+1, lastErr)
+}
+
+// httpStatusError carries the HTTP status code from a raw (non-SDK)
+// backend request so isRetryableError can tell a rate limit or server
+// error apart from a permanent failure without string-matching.
+type httpStatusError struct {
+	statusCode int
+	err        error
+}
+
+func (e *httpStatusError) Error() string { return e.err.Error() }
+func (e *httpStatusError) Unwrap() error { return e.err }
+
+// isRetryableError reports whether err represents a transient failure
+// (HTTP 429 or 5xx) worth retrying, recognizing both go-openai's
+// *openai.APIError (used by the OpenAI-compatible chat-completions
+// backends) and the *httpStatusError raw HTTP backends (Ollama,
+// LocalAI's grammar path) wrap their non-200 responses in.
+func isRetryableError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return isRetryableStatus(reqErr.HTTPStatusCode)
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.statusCode)
+	}
+
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	return code == 429 || (code >= 500 && code < 600)
+}
+
+// dedupeFlashcards removes near-identical cards from a merged result
+// set, which can arise when overlapping chunks cause the same concept to
+// be generated twice. Two cards are considered duplicates when their
+// Fronts are similar enough under normalized Levenshtein distance.
+const dedupeSimilarityThreshold = 0.85
+
+func dedupeFlashcards(cards []Flashcard) []Flashcard {
+	kept := make([]Flashcard, 0, len(cards))
+
+	for _, card := range cards {
+		front := normalizeForDedupe(card.Front)
+
+		duplicate := false
+		for _, existing := range kept {
+			if frontSimilarity(front, normalizeForDedupe(existing.Front)) >= dedupeSimilarityThreshold {
+				duplicate = true
+				break
+			}
+		}
+
+		if !duplicate {
+			kept = append(kept, card)
+		}
+	}
+
+	return kept
+}
+
+func normalizeForDedupe(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// frontSimilarity returns a 0..1 similarity score between two strings
+// derived from normalized Levenshtein edit distance (1 - distance/maxLen).
+func frontSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	maxLen := len([]rune(a))
+	if rl := len([]rune(b)); rl > maxLen {
+		maxLen = rl
+	}
+	if maxLen == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(a, b))/float64(maxLen)
+}
+
+// levenshtein computes the edit distance between a and b using the
+// standard two-row dynamic programming algorithm.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFrontSimilarityMultiByteRunes(t *testing.T) {
+	// "café" (5 bytes, 4 runes) vs "cafe" (4 bytes, 4 runes): a single
+	// rune edit, so similarity should be 1 - 1/4 regardless of byte
+	// length. Normalizing by byte length instead would inflate maxLen to
+	// 5 and understate the edit distance's weight.
+	got := frontSimilarity("café", "cafe")
+	want := 1 - 1.0/4.0
+	if diff := got - want; diff < -1e-9 || diff > 1e-9 {
+		t.Fatalf("frontSimilarity(%q, %q) = %v, want %v", "café", "cafe", got, want)
+	}
+}
+
+func TestFrontSimilarityIdentical(t *testing.T) {
+	if got := frontSimilarity("∫ f(x) dx", "∫ f(x) dx"); got != 1 {
+		t.Fatalf("frontSimilarity of identical strings = %v, want 1", got)
+	}
+}
+
+func TestDedupeFlashcardsDropsNearDuplicateMultiByteFront(t *testing.T) {
+	cards := []Flashcard{
+		{Front: "What is √2?", Back: "An irrational number"},
+		{Front: "What is √2 ?", Back: "An irrational number, approximately 1.41"},
+		{Front: "What is π?", Back: "Approximately 3.14159"},
+	}
+
+	kept := dedupeFlashcards(cards)
+
+	if len(kept) != 2 {
+		t.Fatalf("got %d cards after dedupe, want 2: %+v", len(kept), kept)
+	}
+	if kept[0].Front != cards[0].Front || kept[1].Front != cards[2].Front {
+		t.Fatalf("got fronts %q and %q, want %q and %q", kept[0].Front, kept[1].Front, cards[0].Front, cards[2].Front)
+	}
+}
+
+// fakeLLMBackend returns results or errors from a scripted queue, one per
+// call, and records how many times it was invoked.
+type fakeLLMBackend struct {
+	results []struct {
+		cards []Flashcard
+		err   error
+	}
+	calls int
+}
+
+func (f *fakeLLMBackend) GenerateFlashcards(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	i := f.calls
+	f.calls++
+	if i >= len(f.results) {
+		i = len(f.results) - 1
+	}
+	return f.results[i].cards, f.results[i].err
+}
+
+func TestGenerateWithRetrySucceedsAfterTransientErrors(t *testing.T) {
+	backend := &fakeLLMBackend{}
+	backend.results = append(backend.results,
+		struct {
+			cards []Flashcard
+			err   error
+		}{nil, &httpStatusError{statusCode: 429, err: errors.New("rate limited")}},
+		struct {
+			cards []Flashcard
+			err   error
+		}{nil, &httpStatusError{statusCode: 503, err: errors.New("unavailable")}},
+		struct {
+			cards []Flashcard
+			err   error
+		}{[]Flashcard{{Front: "Q", Back: "A"}}, nil},
+	)
+
+	start := time.Now()
+	cards, err := generateWithRetry(context.Background(), backend, "content", "subject")
+	if err != nil {
+		t.Fatalf("generateWithRetry returned error: %v", err)
+	}
+	if len(cards) != 1 || cards[0].Front != "Q" {
+		t.Fatalf("got cards %+v, want one card with Front=Q", cards)
+	}
+	if backend.calls != 3 {
+		t.Fatalf("got %d calls, want 3 (2 failures + 1 success)", backend.calls)
+	}
+	// Two retries back off retryBaseDelay and 2*retryBaseDelay.
+	if elapsed := time.Since(start); elapsed < retryBaseDelay*3 {
+		t.Fatalf("generateWithRetry returned after %v, want at least %v of backoff", elapsed, retryBaseDelay*3)
+	}
+}
+
+func TestGenerateWithRetryGivesUpOnNonRetryableError(t *testing.T) {
+	backend := &fakeLLMBackend{}
+	backend.results = append(backend.results, struct {
+		cards []Flashcard
+		err   error
+	}{nil, errors.New("permanent failure")})
+
+	_, err := generateWithRetry(context.Background(), backend, "content", "subject")
+	if err == nil {
+		t.Fatal("generateWithRetry returned nil error, want the permanent failure")
+	}
+	if backend.calls != 1 {
+		t.Fatalf("got %d calls, want 1 (non-retryable errors shouldn't be retried)", backend.calls)
+	}
+}
+
+func TestGenerateWithRetryStopsOnExhaustedRetries(t *testing.T) {
+	backend := &fakeLLMBackend{}
+	backend.results = append(backend.results, struct {
+		cards []Flashcard
+		err   error
+	}{nil, &httpStatusError{statusCode: 500, err: errors.New("always fails")}})
+
+	_, err := generateWithRetry(context.Background(), backend, "content", "subject")
+	if err == nil {
+		t.Fatal("generateWithRetry returned nil error, want an error after exhausting retries")
+	}
+	if backend.calls != maxRetries+1 {
+		t.Fatalf("got %d calls, want %d (maxRetries+1 attempts)", backend.calls, maxRetries+1)
+	}
+}
+
+func TestGenerateWithRetryRespectsContextCancellation(t *testing.T) {
+	backend := &fakeLLMBackend{}
+	backend.results = append(backend.results, struct {
+		cards []Flashcard
+		err   error
+	}{nil, &httpStatusError{statusCode: 429, err: errors.New("rate limited")}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // cancelled before the first retry's backoff sleep
+
+	_, err := generateWithRetry(ctx, backend, "content", "subject")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got err=%v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,21 @@
+// Package flashcard defines the core Flashcard type shared by the CLI,
+// the LLM layer, and the spaced-repetition scheduler.
+package flashcard
+
+import "time"
+
+// Flashcard represents a single Anki flashcard, including the
+// spaced-repetition state tracked by the scheduler subpackage.
+type Flashcard struct {
+	Front string   `json:"front"`
+	Back  string   `json:"back"`
+	Tags  []string `json:"tags,omitempty"`
+
+	// SM-2 scheduler state. Zero values mean the card has never been
+	// reviewed; scheduler.Review fills in a default Ease on first use.
+	Interval   int       `json:"interval,omitempty"`
+	Ease       float64   `json:"ease,omitempty"`
+	Reps       int       `json:"reps,omitempty"`
+	Due        time.Time `json:"due,omitempty"`
+	LastReview time.Time `json:"last_review,omitempty"`
+}
@@ -1,130 +1,64 @@
 package main
 
 import (
-	"bytes"
+	"archive/zip"
 	"context"
+	"crypto/sha256"
+	"database/sql"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/ledongthuc/pdf"
-	"github.com/nguyenthenguyen/docx"
-	"github.com/russross/blackfriday/v2"
-	"github.com/sashabaranov/go-openai"
+	_ "github.com/mattn/go-sqlite3"
+
+	"note2anki/flashcard"
+	"note2anki/parser"
 )
 
 // Config holds application configuration
 type Config struct {
+	Provider     string  `json:"provider"` // "anthropic", "openai", "ollama", or "localai"
 	APIKey       string  `json:"api_key"`
 	Model        string  `json:"model"`
+	BaseURL      string  `json:"base_url,omitempty"`
 	MaxTokens    int     `json:"max_tokens"`
 	Temperature  float32 `json:"temperature"`
 	SystemPrompt string  `json:"system_prompt"`
-}
-
-// Flashcard represents a single Anki flashcard
-type Flashcard struct {
-	Front string   `json:"front"`
-	Back  string   `json:"back"`
-	Tags  []string `json:"tags,omitempty"`
-}
-
-// FileParser interface for different file types
-type FileParser interface {
-	Parse(filepath string) (string, error)
-}
-
-// PDFParser implements FileParser for PDF files
-type PDFParser struct{}
-
-func (p *PDFParser) Parse(filepath string) (string, error) {
-	f, r, err := pdf.Open(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open PDF: %w", err)
-	}
-	defer f.Close()
-
-	var buf bytes.Buffer
-	totalPage := r.NumPage()
-
-	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
-		p := r.Page(pageIndex)
-		if p.V.IsNull() {
-			continue
-		}
-
-		text, err := p.GetPlainText(nil)
-		if err != nil {
-			continue
-		}
-		buf.WriteString(text)
-		buf.WriteString("\n")
-	}
-
-	return buf.String(), nil
-}
-
-// DOCXParser implements FileParser for DOCX files
-type DOCXParser struct{}
-
-func (d *DOCXParser) Parse(filepath string) (string, error) {
-	r, err := docx.ReadDocxFile(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read DOCX: %w", err)
-	}
-	defer r.Close()
+	StrictJSON   bool    `json:"strict_json,omitempty"` // constrained decoding + hard failure on schema mismatch
 
-	doc := r.Editable()
-	content := doc.GetContent()
+	// Chunking and concurrency for large documents.
+	ChunkTokens  int `json:"chunk_tokens,omitempty"`  // target size of each chunk sent to the LLM
+	ChunkOverlap int `json:"chunk_overlap,omitempty"` // tokens of trailing context carried into the next chunk
+	Workers      int `json:"workers,omitempty"`       // concurrent chunk requests in flight
 
-	return content, nil
+	// ServerToken, when set, is the bearer token `serve` mode requires on
+	// every request. Leaving it empty runs the API unauthenticated, which
+	// is only appropriate behind a trusted network boundary.
+	ServerToken string `json:"server_token,omitempty"`
 }
 
-// MarkdownParser implements FileParser for Markdown files
-type MarkdownParser struct{}
-
-func (m *MarkdownParser) Parse(filepath string) (string, error) {
-	content, err := os.ReadFile(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read markdown file: %w", err)
-	}
-
-	// Convert markdown to plain text
-	html := blackfriday.Run(content)
-	// Simple HTML stripping (in production, use a proper HTML parser)
-	text := stripHTML(string(html))
-
-	return text, nil
-}
-
-// stripHTML removes HTML tags from text (simplified version)
-func stripHTML(html string) string {
-	// This is a simplified version. In production, use golang.org/x/net/html
-	var result strings.Builder
-	inTag := false
-
-	for _, r := range html {
-		switch r {
-		case '<':
-			inTag = true
-		case '>':
-			inTag = false
-		default:
-			if !inTag {
-				result.WriteRune(r)
-			}
-		}
-	}
+// Defaults for the chunking/concurrency knobs above, used when a config
+// file or -workers flag doesn't set them explicitly.
+const (
+	defaultChunkTokens  = 3000
+	defaultChunkOverlap = 200
+	defaultWorkers      = 4
+)
 
-	return result.String()
-}
+// Flashcard represents a single Anki flashcard. The type lives in the
+// flashcard package so the scheduler subpackage can operate on it too.
+type Flashcard = flashcard.Flashcard
 
 // extractJSON finds and extracts JSON array from Claude's response
 func extractJSON(response string) (string, error) {
@@ -155,96 +89,6 @@ func extractJSON(response string) (string, error) {
 	return jsonContent, nil
 }
 
-// LLMClient handles interaction with the language model
-type LLMClient struct {
-	client *openai.Client
-	config Config
-}
-
-// NewLLMClient creates a new LLM client
-func NewLLMClient(config Config) *LLMClient {
-	clientConfig := openai.DefaultConfig(config.APIKey)
-	clientConfig.BaseURL = "https://api.anthropic.com/v1"
-	client := openai.NewClientWithConfig(clientConfig)
-	return &LLMClient{
-		client: client,
-		config: config,
-	}
-}
-
-// GenerateFlashcards converts text content to flashcards
-func (l *LLMClient) GenerateFlashcards(content string, subject string) ([]Flashcard, error) {
-	systemPrompt := l.config.SystemPrompt
-	if systemPrompt == "" {
-		systemPrompt = `You are an expert educator creating Anki flashcards. 
-		Follow these principles:
-		1. Create atomic cards (one concept per card)
-		2. Make questions clear and unambiguous
-		3. Keep answers concise but complete
-		4. Focus on key concepts, definitions, formulas, and relationships
-		5. Use active recall principles
-		
-		CRITICAL: Respond ONLY with a valid JSON array. Do not include any explanatory text, introductions, or conclusions. 
-		Output format: JSON array of objects with "front" (question) and "back" (answer) fields.
-		Generate comprehensive flashcards covering all important information.`
-	}
-
-	userPrompt := fmt.Sprintf(`Convert the following %s notes into Anki flashcards:
-
-%s
-
-Create flashcards that cover all key concepts, ensuring each card tests a single piece of knowledge.
-Output as a JSON array.`, subject, content)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	resp, err := l.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: l.config.Model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: userPrompt,
-				},
-			},
-			MaxTokens:   l.config.MaxTokens,
-			Temperature: l.config.Temperature,
-		},
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("LLM request failed: %w", err)
-	}
-
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from LLM")
-	}
-
-	// Parse JSON response
-	var flashcards []Flashcard
-	responseContent := resp.Choices[0].Message.Content
-
-	// Extract JSON from Claude's potentially verbose response
-	jsonContent, err := extractJSON(responseContent)
-	if err != nil {
-		// If JSON extraction fails, show the actual response for debugging
-		return nil, fmt.Errorf("failed to extract JSON from response: %w\nActual response: %s", err, responseContent)
-	}
-
-	err = json.Unmarshal([]byte(jsonContent), &flashcards)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse extracted JSON: %w\nExtracted JSON: %s", err, jsonContent)
-	}
-
-	return flashcards, nil
-}
-
 // AnkiExporter handles exporting flashcards to Anki-compatible formats
 type AnkiExporter struct{}
 
@@ -304,39 +148,293 @@ func (e *AnkiExporter) ExportCSV(flashcards []Flashcard, filepath string) error
 	return nil
 }
 
+// sidecarState is what ExportAPKG persists alongside a .apkg file so that
+// re-running the exporter against the same output path updates existing
+// cards' scheduler state instead of creating duplicates.
+type sidecarState struct {
+	Cards map[string]Flashcard `json:"cards"` // keyed by sha256(Front)
+}
+
+// sidecarPath returns the JSON sidecar path for a given .apkg output path.
+func sidecarPath(apkgPath string) string {
+	ext := filepath.Ext(apkgPath)
+	return strings.TrimSuffix(apkgPath, ext) + ".n2a.json"
+}
+
+// frontHash returns the dedupe key for a card: the hex-encoded sha256 of
+// its Front field.
+func frontHash(front string) string {
+	sum := sha256.Sum256([]byte(front))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadSidecar reads previously persisted scheduler state, if any. A
+// missing sidecar is not an error: it just means this is a first export.
+func loadSidecar(path string) (sidecarState, error) {
+	state := sidecarState{Cards: map[string]Flashcard{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read sidecar %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse sidecar %s: %w", path, err)
+	}
+	if state.Cards == nil {
+		state.Cards = map[string]Flashcard{}
+	}
+
+	return state, nil
+}
+
+// saveSidecar persists merged scheduler state next to the .apkg output.
+func saveSidecar(path string, state sidecarState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sidecar: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// mergeScheduleState dedupes incoming flashcards against previously
+// exported ones by hash of Front, carrying over scheduler state for
+// cards that already exist rather than resetting their progress.
+func mergeScheduleState(cards []Flashcard, state sidecarState) []Flashcard {
+	merged := make([]Flashcard, len(cards))
+
+	for i, card := range cards {
+		hash := frontHash(card.Front)
+		if prev, ok := state.Cards[hash]; ok {
+			card.Interval = prev.Interval
+			card.Ease = prev.Ease
+			card.Reps = prev.Reps
+			card.Due = prev.Due
+			card.LastReview = prev.LastReview
+		}
+
+		merged[i] = card
+		state.Cards[hash] = card
+	}
+
+	return merged
+}
+
+// ExportAPKG writes flashcards as a real Anki package: a zip archive
+// containing a SQLite collection.anki2 database plus an empty media
+// manifest. Re-running it against the same filepath reads the JSON
+// sidecar written alongside the .apkg so previously exported cards are
+// updated in place (by hash of Front) instead of duplicated.
+func (e *AnkiExporter) ExportAPKG(flashcards []Flashcard, filepath string) error {
+	prev, err := loadSidecar(sidecarPath(filepath))
+	if err != nil {
+		return err
+	}
+
+	merged := mergeScheduleState(flashcards, prev)
+
+	dbPath, err := buildCollectionDB(merged)
+	if err != nil {
+		return fmt.Errorf("failed to build collection database: %w", err)
+	}
+	defer os.Remove(dbPath)
+
+	if err := writeAPKG(filepath, dbPath); err != nil {
+		return fmt.Errorf("failed to write apkg: %w", err)
+	}
+
+	if err := saveSidecar(sidecarPath(filepath), prev); err != nil {
+		return fmt.Errorf("failed to persist scheduler state: %w", err)
+	}
+
+	return nil
+}
+
+// buildCollectionDB creates a temporary Anki-schema SQLite database
+// (collection.anki2) populated with one note/card pair per flashcard,
+// and returns its path. The caller is responsible for removing it.
+func buildCollectionDB(cards []Flashcard) (string, error) {
+	tmp, err := os.CreateTemp("", "collection-*.anki2")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp database: %w", err)
+	}
+	dbPath := tmp.Name()
+	tmp.Close()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return dbPath, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	const schema = `
+CREATE TABLE col (
+	id integer primary key, crt integer, mod integer, scm integer,
+	ver integer, dty integer, usn integer, ls integer,
+	conf text, models text, decks text, dconf text, tags text
+);
+CREATE TABLE notes (
+	id integer primary key, guid text, mid integer, mod integer,
+	usn integer, tags text, flds text, sfld text, csum integer,
+	flags integer, data text
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer, did integer, ord integer,
+	mod integer, usn integer, type integer, queue integer,
+	due integer, ivl integer, factor integer, reps integer,
+	lapses integer, left integer, odue integer, odid integer,
+	flags integer, data text
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer, usn integer, ease integer,
+	ivl integer, lastIvl integer, factor integer, time integer, type integer
+);
+CREATE TABLE graves (usn integer, oid integer, type integer);
+`
+	if _, err := db.Exec(schema); err != nil {
+		return dbPath, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	now := time.Now()
+	modelID := now.UnixNano() / int64(time.Millisecond)
+	deckID := int64(1)
+
+	// A genuine Basic notetype: Anki's model schema requires qfmt/afmt on
+	// every template to render a card, and ord/sticky/rtl/font/size on
+	// every field, not just a name.
+	models := fmt.Sprintf(`{"%d":{
+		"id":%d,"name":"Basic","type":0,"sortf":0,"did":%d,"usn":-1,"mod":%d,
+		"flds":[
+			{"name":"Front","ord":0,"sticky":false,"rtl":false,"font":"Arial","size":20},
+			{"name":"Back","ord":1,"sticky":false,"rtl":false,"font":"Arial","size":20}
+		],
+		"tmpls":[
+			{"name":"Card 1","ord":0,"qfmt":"{{Front}}","afmt":"{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}","did":null,"bqfmt":"","bafmt":"","bfont":"Arial","bsize":0}
+		],
+		"css":".card {\n font-family: arial;\n font-size: 20px;\n text-align: center;\n color: black;\n background-color: white;\n}\n",
+		"latexPre":"","latexPost":"","req":[[0,"any",[0]]]
+	}}`, modelID, modelID, deckID, now.Unix())
+	decks := fmt.Sprintf(`{"%d":{"id":%d,"name":"Default"}}`, deckID, deckID)
+
+	_, err = db.Exec(`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, '{}', ?, ?, '{}', '{}')`,
+		now.Unix(), now.UnixMilli(), now.UnixMilli(), models, decks)
+	if err != nil {
+		return dbPath, fmt.Errorf("failed to seed collection row: %w", err)
+	}
+
+	for i, card := range cards {
+		noteID := modelID + int64(i)
+		cardID := noteID
+
+		flds := card.Front + "\x1f" + card.Back
+		sum := sha256.Sum256([]byte(card.Front))
+		csum := int64(sum[0])<<24 | int64(sum[1])<<16 | int64(sum[2])<<8 | int64(sum[3])
+
+		_, err = db.Exec(`INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`,
+			noteID, fmt.Sprintf("n2a%d", noteID), modelID, now.Unix(),
+			strings.Join(card.Tags, " "), flds, card.Front, csum)
+		if err != nil {
+			return dbPath, fmt.Errorf("failed to insert note: %w", err)
+		}
+
+		// A card that has never been reviewed (Reps == 0) is still new
+		// in Anki's terms: type/queue 0, due is its position in the new
+		// card queue. A card with scheduler state behind it is a review
+		// card: type/queue 2, due is measured in days since col.crt, not
+		// as an absolute epoch day, or Anki will misplace it relative to
+		// "today".
+		cardType, queue, due := 0, 0, int64(i)
+		if card.Reps > 0 {
+			cardType, queue = 2, 2
+			due = int64(card.Due.Sub(time.Unix(now.Unix(), 0)).Hours() / 24)
+		}
+
+		_, err = db.Exec(`INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, ?, ?, ?, ?, ?, ?, 0, 0, 0, 0, 0, '')`,
+			cardID, noteID, deckID, now.Unix(), cardType, queue, due, card.Interval, int(card.Ease*1000), card.Reps)
+		if err != nil {
+			return dbPath, fmt.Errorf("failed to insert card: %w", err)
+		}
+	}
+
+	return dbPath, nil
+}
+
+// writeAPKG zips dbPath (the built collection.anki2) and an empty media
+// manifest into the final .apkg at outPath.
+func writeAPKG(outPath, dbPath string) error {
+	out, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+
+	dbFile, err := os.Open(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to reopen collection database: %w", err)
+	}
+	defer dbFile.Close()
+
+	dbEntry, err := zw.Create("collection.anki2")
+	if err != nil {
+		return fmt.Errorf("failed to add collection.anki2 to archive: %w", err)
+	}
+	if _, err := io.Copy(dbEntry, dbFile); err != nil {
+		return fmt.Errorf("failed to write collection.anki2: %w", err)
+	}
+
+	mediaEntry, err := zw.Create("media")
+	if err != nil {
+		return fmt.Errorf("failed to add media manifest to archive: %w", err)
+	}
+	if _, err := mediaEntry.Write([]byte("{}")); err != nil {
+		return fmt.Errorf("failed to write media manifest: %w", err)
+	}
+
+	return zw.Close()
+}
+
 // ProcessingPipeline orchestrates the conversion process
 type ProcessingPipeline struct {
-	parser   FileParser
-	llm      *LLMClient
-	exporter *AnkiExporter
+	fileParser parser.FileParser
+	llm        LLMBackend
+	exporter   *AnkiExporter
+	config     Config
 }
 
 // NewProcessingPipeline creates a new processing pipeline
-func NewProcessingPipeline(config Config) *ProcessingPipeline {
+func NewProcessingPipeline(config Config) (*ProcessingPipeline, error) {
+	backend, err := NewLLMBackend(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize LLM backend: %w", err)
+	}
+
 	return &ProcessingPipeline{
-		llm:      NewLLMClient(config),
+		llm:      backend,
 		exporter: &AnkiExporter{},
-	}
+		config:   config,
+	}, nil
 }
 
-// Process converts input file to Anki flashcards
-func (p *ProcessingPipeline) Process(inputPath, outputPath string, dryRun bool) error {
-	// Determine file type and select parser
-	ext := strings.ToLower(filepath.Ext(inputPath))
-	switch ext {
-	case ".pdf":
-		p.parser = &PDFParser{}
-	case ".docx":
-		p.parser = &DOCXParser{}
-	case ".md", ".markdown":
-		p.parser = &MarkdownParser{}
-	default:
-		return fmt.Errorf("unsupported file format: %s", ext)
+// Process converts input file to Anki flashcards. Large documents are
+// split into overlapping chunks and dispatched through a worker pool
+// (see chunk.go) rather than sent to the LLM in one call, so ctx
+// cancellation (Ctrl-C) can abort in-flight chunk requests.
+func (p *ProcessingPipeline) Process(ctx context.Context, inputPath, outputPath string, dryRun bool) error {
+	// Select a parser from the registry for this file's extension
+	fileParser, err := parser.Default.ParserFor(inputPath)
+	if err != nil {
+		return err
 	}
+	p.fileParser = fileParser
 
 	// Extract text content
 	fmt.Println("üìñ Extracting text from file...")
-	content, err := p.parser.Parse(inputPath)
+	content, err := p.fileParser.Parse(inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to parse file: %w", err)
 	}
@@ -348,20 +446,12 @@ func (p *ProcessingPipeline) Process(inputPath, outputPath string, dryRun bool)
 	fmt.Printf("‚úÖ Extracted %d characters of text\n", len(content))
 
 	// Determine subject from filename
+	ext := strings.ToLower(filepath.Ext(inputPath))
 	subject := strings.TrimSuffix(filepath.Base(inputPath), ext)
 
-	// Generate flashcards using Claude AI
-	fmt.Println("ü§ñ Generating flashcards with Claude AI...")
-	flashcards, err := p.llm.GenerateFlashcards(content, subject)
+	flashcards, err := p.ProcessContent(ctx, content, subject)
 	if err != nil {
-		return fmt.Errorf("failed to generate flashcards: %w", err)
-	}
-
-	fmt.Printf("‚úÖ Generated %d flashcards\n", len(flashcards))
-
-	// Add subject as tag
-	for i := range flashcards {
-		flashcards[i].Tags = append(flashcards[i].Tags, subject)
+		return err
 	}
 
 	// Dry run mode - preview cards
@@ -384,6 +474,8 @@ func (p *ProcessingPipeline) Process(inputPath, outputPath string, dryRun bool)
 	switch outputExt {
 	case ".csv":
 		err = p.exporter.ExportCSV(flashcards, outputPath)
+	case ".apkg":
+		err = p.exporter.ExportAPKG(flashcards, outputPath)
 	case ".txt", ".tsv":
 		err = p.exporter.ExportTXT(flashcards, outputPath)
 	default:
@@ -399,12 +491,44 @@ func (p *ProcessingPipeline) Process(inputPath, outputPath string, dryRun bool)
 	return nil
 }
 
+// ProcessContent runs already-extracted text through chunking, concurrent
+// flashcard generation, and dedup, tagging every card with subject. It is
+// the shared core behind both the file-based Process above and the HTTP
+// server's upload handlers (see serve.go), which extract content from a
+// request body instead of from disk.
+func (p *ProcessingPipeline) ProcessContent(ctx context.Context, content, subject string) ([]Flashcard, error) {
+	chunks := chunkText(content, p.config.ChunkTokens, p.config.ChunkOverlap)
+	fmt.Printf("🧩 Split into %d chunk(s)\n", len(chunks))
+
+	// Generate flashcards, dispatching chunks concurrently across a
+	// worker pool and retrying transient (429/5xx) backend errors.
+	fmt.Println("ü§ñ Generating flashcards...")
+	flashcards, err := processChunks(ctx, p.llm, chunks, subject, p.config.Workers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate flashcards: %w", err)
+	}
+
+	flashcards = dedupeFlashcards(flashcards)
+
+	fmt.Printf("‚úÖ Generated %d flashcards\n", len(flashcards))
+
+	for i := range flashcards {
+		flashcards[i].Tags = append(flashcards[i].Tags, subject)
+	}
+
+	return flashcards, nil
+}
+
 // LoadConfig loads configuration from file or environment
 func LoadConfig(configPath string) (Config, error) {
 	config := Config{
-		Model:       "claude-3-5-haiku-20241022",
-		MaxTokens:   2000,
-		Temperature: 0.7,
+		Provider:     "anthropic",
+		Model:        "claude-3-5-haiku-20241022",
+		MaxTokens:    2000,
+		Temperature:  0.7,
+		ChunkTokens:  defaultChunkTokens,
+		ChunkOverlap: defaultChunkOverlap,
+		Workers:      defaultWorkers,
 	}
 
 	// Load .env file if it exists
@@ -423,27 +547,58 @@ func LoadConfig(configPath string) (Config, error) {
 		}
 	}
 
-	// Override with environment variable if set
-	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
-		config.APIKey = apiKey
+	// Override with environment variables if set, matching the selected provider
+	switch config.Provider {
+	case "openai":
+		if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+			config.APIKey = apiKey
+		}
+	case "localai":
+		if apiKey := os.Getenv("LOCALAI_API_KEY"); apiKey != "" {
+			config.APIKey = apiKey
+		}
+	case "ollama":
+		// Ollama runs unauthenticated by default; no API key required.
+	default:
+		if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
+			config.APIKey = apiKey
+		}
 	}
 
-	if config.APIKey == "" {
-		return config, fmt.Errorf("API key not found. Set ANTHROPIC_API_KEY environment variable or provide in config file")
+	if config.APIKey == "" && config.Provider != "ollama" {
+		return config, fmt.Errorf("API key not found. Set ANTHROPIC_API_KEY (or the env var matching -provider) or provide one in the config file")
 	}
 
 	return config, nil
 }
 
 func main() {
+	// The "serve" subcommand runs an HTTP API instead of the one-shot
+	// file-conversion CLI below, so it gets its own flag set (e.g.
+	// -listen) rather than sharing flag.CommandLine's positional args.
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			log.Fatalf("‚ùå Server error: %v", err)
+		}
+		return
+	}
+
 	// Parse command-line arguments
 	var (
 		configPath string
+		provider   string
+		baseURL    string
+		strictJSON bool
+		workers    int
 		dryRun     bool
 		help       bool
 	)
 
 	flag.StringVar(&configPath, "config", "", "Path to configuration file")
+	flag.StringVar(&provider, "provider", "", "LLM provider: anthropic, openai, ollama, or localai")
+	flag.StringVar(&baseURL, "base-url", "", "Override the provider's API base URL")
+	flag.BoolVar(&strictJSON, "strict-json", false, "Use constrained decoding and hard-fail on any schema mismatch instead of best-effort JSON extraction")
+	flag.IntVar(&workers, "workers", 0, "Concurrent chunk requests in flight (default: from config, or 4)")
 	flag.BoolVar(&dryRun, "dry-run", false, "Preview flashcards without saving")
 	flag.BoolVar(&help, "help", false, "Show help message")
 	flag.Parse()
@@ -452,15 +607,21 @@ func main() {
 		fmt.Println("Note to Anki - Convert study notes to Anki flashcards")
 		fmt.Println("\nUsage:")
 		fmt.Println("  note2anki [options] <input-file> <output-file>")
+		fmt.Println("  note2anki serve [-listen :8080] [options]")
 		fmt.Println("\nOptions:")
 		fmt.Println("  -config string   Path to configuration file")
+		fmt.Println("  -provider string LLM provider: anthropic, openai, ollama, or localai")
+		fmt.Println("  -base-url string Override the provider's API base URL")
+		fmt.Println("  -strict-json    Constrain decoding and hard-fail on schema mismatch")
+		fmt.Println("  -workers int    Concurrent chunk requests in flight (default 4)")
 		fmt.Println("  -dry-run        Preview flashcards without saving")
 		fmt.Println("  -help           Show this help message")
-		fmt.Println("\nSupported input formats: PDF, DOCX, MD")
-		fmt.Println("Supported output formats: TXT (tab-separated), CSV")
+		fmt.Println("\nSupported input formats: PDF, DOCX, MD, EPUB, HTML, PPTX")
+		fmt.Println("Supported output formats: TXT (tab-separated), CSV, APKG")
 		fmt.Println("\nExample:")
 		fmt.Println("  note2anki notes.pdf flashcards.txt")
 		fmt.Println("  note2anki -dry-run lecture.docx preview.csv")
+		fmt.Println("  note2anki serve -listen :8080")
 		os.Exit(0)
 	}
 
@@ -478,11 +639,32 @@ func main() {
 		log.Fatalf("‚ùå Configuration error: %v", err)
 	}
 
+	if provider != "" {
+		config.Provider = provider
+	}
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	if strictJSON {
+		config.StrictJSON = true
+	}
+	if workers > 0 {
+		config.Workers = workers
+	}
+
 	// Create and run pipeline
-	pipeline := NewProcessingPipeline(config)
+	pipeline, err := NewProcessingPipeline(config)
+	if err != nil {
+		log.Fatalf("‚ùå Configuration error: %v", err)
+	}
+
+	// Ctrl-C cancels any in-flight chunk requests instead of leaving them
+	// to run to completion after the user has already given up.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	startTime := time.Now()
-	err = pipeline.Process(inputPath, outputPath, dryRun)
+	err = pipeline.Process(ctx, inputPath, outputPath, dryRun)
 	if err != nil {
 		log.Fatalf("‚ùå Processing failed: %v", err)
 	}
@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/nguyenthenguyen/docx"
+)
+
+// DOCXParser implements FileParser for DOCX files.
+type DOCXParser struct{}
+
+func init() {
+	RegisterParser(".docx", &DOCXParser{})
+}
+
+func (d *DOCXParser) Parse(path string) (string, error) {
+	r, err := docx.ReadDocxFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX: %w", err)
+	}
+	defer r.Close()
+
+	doc := r.Editable()
+	return doc.GetContent(), nil
+}
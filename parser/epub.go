@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// EPUBParser implements FileParser for EPUB e-books: it reads the
+// package document's manifest/spine to find the content documents in
+// reading order, then strips each one's markup, separating them with
+// SectionMarker so the pipeline can chunk by content document.
+type EPUBParser struct{}
+
+func init() {
+	RegisterParser(".epub", &EPUBParser{})
+}
+
+// epubContainer is META-INF/container.xml, which points at the package
+// document (commonly content.opf) that actually lists the book's
+// contents.
+type epubContainer struct {
+	Rootfiles struct {
+		Rootfile []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfile"`
+	} `xml:"rootfiles"`
+}
+
+// epubPackage is the OPF package document: a manifest of every item in
+// the book keyed by id, and a spine listing which manifest items make
+// up the reading order.
+type epubPackage struct {
+	Manifest struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"item"`
+	} `xml:"manifest"`
+	Spine struct {
+		ItemRefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+func (e *EPUBParser) Parse(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open EPUB: %w", err)
+	}
+	defer r.Close()
+
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+
+	opfPath, err := epubOPFPath(files)
+	if err != nil {
+		return "", err
+	}
+
+	pkg, err := readEPUBPackage(files, opfPath)
+	if err != nil {
+		return "", err
+	}
+
+	manifest := make(map[string]string, len(pkg.Manifest.Items))
+	for _, item := range pkg.Manifest.Items {
+		manifest[item.ID] = item.Href
+	}
+	opfDir := path.Dir(opfPath)
+
+	var sections []string
+	for _, ref := range pkg.Spine.ItemRefs {
+		href, ok := manifest[ref.IDRef]
+		if !ok {
+			continue
+		}
+
+		text, err := readEPUBItemText(files, path.Join(opfDir, href))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(text) != "" {
+			sections = append(sections, text)
+		}
+	}
+
+	return strings.Join(sections, SectionMarker), nil
+}
+
+func epubOPFPath(files map[string]*zip.File) (string, error) {
+	f, ok := files["META-INF/container.xml"]
+	if !ok {
+		return "", fmt.Errorf("EPUB missing META-INF/container.xml")
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read EPUB container: %w", err)
+	}
+	defer rc.Close()
+
+	var container epubContainer
+	if err := xml.NewDecoder(rc).Decode(&container); err != nil {
+		return "", fmt.Errorf("failed to parse EPUB container: %w", err)
+	}
+	if len(container.Rootfiles.Rootfile) == 0 {
+		return "", fmt.Errorf("EPUB container has no rootfile")
+	}
+
+	return container.Rootfiles.Rootfile[0].FullPath, nil
+}
+
+func readEPUBPackage(files map[string]*zip.File, opfPath string) (*epubPackage, error) {
+	f, ok := files[opfPath]
+	if !ok {
+		return nil, fmt.Errorf("EPUB package document %s not found", opfPath)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EPUB package document: %w", err)
+	}
+	defer rc.Close()
+
+	var pkg epubPackage
+	if err := xml.NewDecoder(rc).Decode(&pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse EPUB package document: %w", err)
+	}
+
+	return &pkg, nil
+}
+
+func readEPUBItemText(files map[string]*zip.File, itemPath string) (string, error) {
+	f, ok := files[itemPath]
+	if !ok {
+		return "", fmt.Errorf("EPUB content item %s not found", itemPath)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read EPUB content item: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read EPUB content item: %w", err)
+	}
+
+	return stripHTML(string(data)), nil
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"archive/zip"
+	"os"
+	"strings"
+	"testing"
+)
+
+// writeEPUBFixture builds a minimal EPUB whose spine lists chapters in an
+// order that doesn't match the files' lexical names, so a test can tell
+// spine-order extraction apart from an accidental directory-listing order.
+func writeEPUBFixture(t *testing.T) string {
+	t.Helper()
+
+	f, err := os.CreateTemp("", "fixture-*.epub")
+	if err != nil {
+		t.Fatalf("create temp epub: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0"?>
+<container><rootfiles><rootfile full-path="content.opf"/></rootfiles></container>`,
+		"content.opf": `<?xml version="1.0"?>
+<package>
+	<manifest>
+		<item id="ch-z" href="z-chapter.xhtml"/>
+		<item id="ch-a" href="a-chapter.xhtml"/>
+	</manifest>
+	<spine>
+		<itemref idref="ch-z"/>
+		<itemref idref="ch-a"/>
+	</spine>
+</package>`,
+		"z-chapter.xhtml": `<html><body><p>First in spine order</p></body></html>`,
+		"a-chapter.xhtml": `<html><body><p>Second in spine order</p></body></html>`,
+	}
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create entry %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write entry %s: %v", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close epub zip: %v", err)
+	}
+
+	return f.Name()
+}
+
+func TestEPUBParserFollowsSpineOrder(t *testing.T) {
+	path := writeEPUBFixture(t)
+	defer os.Remove(path)
+
+	text, err := (&EPUBParser{}).Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	sections := strings.Split(text, SectionMarker)
+	if len(sections) != 2 {
+		t.Fatalf("got %d sections, want 2: %q", len(sections), text)
+	}
+	if !strings.Contains(sections[0], "First in spine order") {
+		t.Fatalf("first section = %q, want the spine's first item (z-chapter, not a-chapter)", sections[0])
+	}
+	if !strings.Contains(sections[1], "Second in spine order") {
+		t.Fatalf("second section = %q, want the spine's second item", sections[1])
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLParser implements FileParser for standalone HTML files, using a
+// proper tokenizer rather than the naive tag-stripping MarkdownParser
+// and EPUBParser rely on for markup they've already generated or that's
+// known to be well-formed XHTML.
+type HTMLParser struct{}
+
+func init() {
+	RegisterParser(".html", &HTMLParser{})
+	RegisterParser(".htm", &HTMLParser{})
+}
+
+func (h *HTMLParser) Parse(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open HTML file: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := html.Parse(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var buf strings.Builder
+	extractHTMLText(doc, &buf)
+
+	return buf.String(), nil
+}
+
+// blockElements are the HTML elements whose boundary should read as a
+// line break once rendered to plain text.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "tr": true,
+}
+
+// extractHTMLText walks the parsed HTML tree, writing the text content
+// of every node and skipping non-visible elements (script/style).
+func extractHTMLText(n *html.Node, buf *strings.Builder) {
+	if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+		return
+	}
+
+	if n.Type == html.TextNode {
+		buf.WriteString(n.Data)
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		extractHTMLText(c, buf)
+	}
+
+	if n.Type == html.ElementNode && blockElements[n.Data] {
+		buf.WriteString("\n")
+	}
+}
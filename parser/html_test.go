@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func writeHTMLFixture(t *testing.T, content string) string {
+	t.Helper()
+
+	f, err := os.CreateTemp("", "fixture-*.html")
+	if err != nil {
+		t.Fatalf("create temp html: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("write html fixture: %v", err)
+	}
+	return f.Name()
+}
+
+func TestHTMLParserStripsScriptAndStyle(t *testing.T) {
+	path := writeHTMLFixture(t, `<html><head><style>body { color: red; }</style></head>
+<body>
+<script>alert("should not appear")</script>
+<p>Visible text</p>
+</body></html>`)
+	defer os.Remove(path)
+
+	text, err := (&HTMLParser{}).Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if strings.Contains(text, "should not appear") || strings.Contains(text, "color: red") {
+		t.Fatalf("got %q, want script/style content stripped", text)
+	}
+	if !strings.Contains(text, "Visible text") {
+		t.Fatalf("got %q, want it to contain the visible paragraph text", text)
+	}
+}
+
+func TestHTMLParserInsertsLineBreaksAtBlockElements(t *testing.T) {
+	path := writeHTMLFixture(t, `<html><body><p>First paragraph</p><p>Second paragraph</p></body></html>`)
+	defer os.Remove(path)
+
+	text, err := (&HTMLParser{}).Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	first := strings.Index(text, "First paragraph")
+	second := strings.Index(text, "Second paragraph")
+	if first == -1 || second == -1 {
+		t.Fatalf("got %q, want both paragraphs present", text)
+	}
+	between := text[first+len("First paragraph") : second]
+	if !strings.Contains(between, "\n") {
+		t.Fatalf("got %q between paragraphs, want a line break at the </p> boundary", between)
+	}
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// MarkdownParser implements FileParser for Markdown files, separating
+// sections at heading boundaries with SectionMarker so the pipeline can
+// chunk a large document by heading.
+type MarkdownParser struct{}
+
+func init() {
+	RegisterParser(".md", &MarkdownParser{})
+	RegisterParser(".markdown", &MarkdownParser{})
+}
+
+// markdownHeading matches an ATX heading line in the source markdown,
+// used to mark section boundaries before the heading structure is lost
+// to HTML conversion and stripping.
+var markdownHeading = regexp.MustCompile(`(?m)^#{1,6}[ \t]+.+$`)
+
+func (m *MarkdownParser) Parse(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read markdown file: %w", err)
+	}
+
+	// Split on heading boundaries in the original source, before
+	// converting each section, so the chunker can later split by
+	// heading instead of falling back to a blind paragraph split.
+	sections := splitOnHeadings(string(content))
+
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		html := blackfriday.Run([]byte(section))
+		// Simple HTML stripping (in production, use a proper HTML parser)
+		text := stripHTML(string(html))
+		if strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	return strings.Join(parts, SectionMarker), nil
+}
+
+// splitOnHeadings breaks markdown source into sections, each starting
+// at an ATX heading of any level (# through ######). Content before the
+// first heading (if any) becomes its own leading section.
+func splitOnHeadings(content string) []string {
+	locs := markdownHeading.FindAllStringIndex(content, -1)
+	if len(locs) == 0 {
+		return []string{content}
+	}
+
+	var sections []string
+	if locs[0][0] > 0 {
+		sections = append(sections, content[:locs[0][0]])
+	}
+	for i, loc := range locs {
+		end := len(content)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		sections = append(sections, content[loc[0]:end])
+	}
+
+	return sections
+}
+
+// stripHTML removes HTML tags from text (simplified version)
+func stripHTML(html string) string {
+	// This is a simplified version. HTMLParser uses golang.org/x/net/html
+	// for real HTML documents; this stays lightweight since it only sees
+	// blackfriday's own output and EPUB's XHTML content documents. It
+	// drops tags only: text inside <script>/<style> is kept.
+	var result strings.Builder
+	inTag := false
+
+	for _, r := range html {
+		switch r {
+		case '<':
+			inTag = true
+		case '>':
+			inTag = false
+		default:
+			if !inTag {
+				result.WriteRune(r)
+			}
+		}
+	}
+
+	return result.String()
+}
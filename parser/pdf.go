@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDFParser implements FileParser for PDF files, separating pages with
+// SectionMarker so the pipeline can chunk a large document by page.
+type PDFParser struct{}
+
+func init() {
+	RegisterParser(".pdf", &PDFParser{})
+}
+
+func (p *PDFParser) Parse(path string) (string, error) {
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	totalPage := r.NumPage()
+
+	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
+		page := r.Page(pageIndex)
+		if page.V.IsNull() {
+			continue
+		}
+
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(SectionMarker)
+		}
+		buf.WriteString(text)
+	}
+
+	return buf.String(), nil
+}
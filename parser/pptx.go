@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PPTXParser implements FileParser for PowerPoint decks: it unzips the
+// archive and reads the text runs out of each slide's XML, separating
+// slides with SectionMarker so the pipeline can chunk by slide.
+type PPTXParser struct{}
+
+func init() {
+	RegisterParser(".pptx", &PPTXParser{})
+}
+
+// pptxSlideXML models just enough of a slide's DrawingML to pull out
+// the text runs of shapes that sit directly in the slide's shape tree.
+// Runs inside group shapes (p:grpSp) or graphic frames such as tables
+// are not read.
+type pptxSlideXML struct {
+	Texts []string `xml:"cSld>spTree>sp>txBody>p>r>t"`
+}
+
+func (p *PPTXParser) Parse(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PPTX: %w", err)
+	}
+	defer r.Close()
+
+	var slideFiles []*zip.File
+	for _, f := range r.File {
+		if strings.HasPrefix(f.Name, "ppt/slides/slide") && strings.HasSuffix(f.Name, ".xml") {
+			slideFiles = append(slideFiles, f)
+		}
+	}
+
+	sort.Slice(slideFiles, func(i, j int) bool {
+		return slideNumber(slideFiles[i].Name) < slideNumber(slideFiles[j].Name)
+	})
+
+	var sections []string
+	for _, f := range slideFiles {
+		text, err := readPPTXSlideText(f)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(text) != "" {
+			sections = append(sections, text)
+		}
+	}
+
+	return strings.Join(sections, SectionMarker), nil
+}
+
+func readPPTXSlideText(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read slide %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	var slide pptxSlideXML
+	if err := xml.NewDecoder(rc).Decode(&slide); err != nil {
+		return "", fmt.Errorf("failed to parse slide %s: %w", f.Name, err)
+	}
+
+	return strings.Join(slide.Texts, " "), nil
+}
+
+// slideNumber extracts the numeric index from a "ppt/slides/slideN.xml"
+// path so slides sort in presentation order instead of lexical order
+// (slide10.xml would otherwise sort before slide2.xml).
+func slideNumber(name string) int {
+	base := strings.TrimPrefix(name, "ppt/slides/slide")
+	base = strings.TrimSuffix(base, ".xml")
+	n, err := strconv.Atoi(base)
+	if err != nil {
+		return 0
+	}
+	return n
+}
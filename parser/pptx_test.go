@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// writeSlideXML returns the minimal DrawingML for a slide containing a
+// single text run.
+func writeSlideXML(text string) string {
+	return fmt.Sprintf(`<?xml version="1.0"?>
+<p:sld><p:cSld><p:spTree><p:sp><p:txBody><a:p><a:r><a:t>%s</a:t></a:r></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:sld>`, text)
+}
+
+// writePPTXFixture builds a minimal PPTX with slides 1, 2, and 10, so a
+// lexical sort (which would put slide10 before slide2) can be told apart
+// from the numeric sort slide ordering requires.
+func writePPTXFixture(t *testing.T) string {
+	t.Helper()
+
+	f, err := os.CreateTemp("", "fixture-*.pptx")
+	if err != nil {
+		t.Fatalf("create temp pptx: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	slides := map[string]string{
+		"ppt/slides/slide1.xml":  writeSlideXML("one"),
+		"ppt/slides/slide2.xml":  writeSlideXML("two"),
+		"ppt/slides/slide10.xml": writeSlideXML("ten"),
+	}
+	for name, content := range slides {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create entry %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write entry %s: %v", name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close pptx zip: %v", err)
+	}
+
+	return f.Name()
+}
+
+func TestPPTXParserSortsSlidesNumerically(t *testing.T) {
+	path := writePPTXFixture(t)
+	defer os.Remove(path)
+
+	text, err := (&PPTXParser{}).Parse(path)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	sections := strings.Split(text, SectionMarker)
+	if len(sections) != 3 {
+		t.Fatalf("got %d sections, want 3: %q", len(sections), text)
+	}
+
+	want := []string{"one", "two", "ten"}
+	for i, w := range want {
+		if !strings.Contains(sections[i], w) {
+			t.Fatalf("section %d = %q, want slide %q (numeric order 1, 2, 10, not lexical 1, 10, 2)", i, sections[i], w)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package parser provides the FileParser interface and a registry
+// mapping file extensions (and MIME types) to implementations, so
+// third-party binaries embedding note2anki can add support for
+// additional formats without touching the core pipeline.
+package parser
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SectionMarker separates the natural sections a parser finds in a
+// document (PDF page breaks, Markdown headings, EPUB/PPTX items) so the
+// processing pipeline's chunker can split on them instead of falling
+// back to a blind paragraph split.
+const SectionMarker = "\f"
+
+// FileParser converts the file at path into its plain-text content.
+type FileParser interface {
+	Parse(path string) (string, error)
+}
+
+// extraMIMETypes fills in MIME types for extensions the local system's
+// mime.types database doesn't reliably know about, so Register can still
+// index them for ParserForMIME.
+var extraMIMETypes = map[string]string{
+	".epub": "application/epub+zip",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// Registry maps file extensions and MIME types to FileParser
+// implementations. The zero value is not usable; construct one with
+// NewRegistry.
+type Registry struct {
+	mu     sync.RWMutex
+	byExt  map[string]FileParser
+	byMIME map[string]FileParser
+}
+
+// NewRegistry creates an empty parser registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byExt:  map[string]FileParser{},
+		byMIME: map[string]FileParser{},
+	}
+}
+
+// Register associates ext (e.g. ".pdf") with p, indexing it by MIME type
+// too when one is known, so ParserForMIME can serve callers (such as an
+// HTTP upload handler) that only have a Content-Type.
+func (r *Registry) Register(ext string, p FileParser) {
+	ext = strings.ToLower(ext)
+
+	mimeType := mime.TypeByExtension(ext)
+	if mimeType == "" {
+		mimeType = extraMIMETypes[ext]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byExt[ext] = p
+	if mimeType != "" {
+		r.byMIME[stripMIMEParams(mimeType)] = p
+	}
+}
+
+// ParserFor returns the FileParser registered for path's extension.
+func (r *Registry) ParserFor(path string) (FileParser, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	r.mu.RLock()
+	p, ok := r.byExt[ext]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format: %s", ext)
+	}
+	return p, nil
+}
+
+// ParserForMIME returns the FileParser registered for mimeType (e.g.
+// from an HTTP upload's Content-Type header).
+func (r *Registry) ParserForMIME(mimeType string) (FileParser, error) {
+	mimeType = stripMIMEParams(mimeType)
+
+	r.mu.RLock()
+	p, ok := r.byMIME[mimeType]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported MIME type: %s", mimeType)
+	}
+	return p, nil
+}
+
+// Extensions returns the file extensions currently registered, sorted,
+// so callers (such as an HTTP "supported formats" endpoint) can report
+// the live set instead of hard-coding it.
+func (r *Registry) Extensions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	exts := make([]string, 0, len(r.byExt))
+	for ext := range r.byExt {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+func stripMIMEParams(mimeType string) string {
+	if idx := strings.Index(mimeType, ";"); idx != -1 {
+		mimeType = mimeType[:idx]
+	}
+	return strings.TrimSpace(mimeType)
+}
+
+// Default is the registry built-in parsers (PDF, DOCX, Markdown, EPUB,
+// HTML, PPTX) add themselves to via init(). Call RegisterParser to
+// extend it with additional formats.
+var Default = NewRegistry()
+
+// RegisterParser adds p to Default under ext. Third-party binaries
+// embedding note2anki call this from their own init() to add support
+// for formats beyond the built-in set.
+func RegisterParser(ext string, p FileParser) {
+	Default.Register(ext, p)
+}
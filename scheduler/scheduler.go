@@ -0,0 +1,56 @@
+// Package scheduler implements a classic SM-2 spaced-repetition recurrence
+// over flashcard.Flashcard, the same algorithm used by SuperMemo and the
+// original Anki scheduler.
+package scheduler
+
+import (
+	"math"
+	"time"
+
+	"note2anki/flashcard"
+)
+
+// defaultEase is SM-2's starting ease factor, applied the first time a
+// card is reviewed.
+const defaultEase = 2.5
+
+// minEase is the floor SM-2 clamps the ease factor to so that poorly
+// remembered cards never spiral into ever-shrinking intervals.
+const minEase = 1.3
+
+// Review applies the SM-2 recurrence to card given a 0-5 recall quality
+// rating (Anki/SuperMemo convention: <3 is a lapse) and returns card for
+// convenience. card is mutated in place.
+func Review(card *flashcard.Flashcard, quality int) *flashcard.Flashcard {
+	if card.Ease == 0 {
+		card.Ease = defaultEase
+	}
+
+	if quality < 3 {
+		card.Reps = 0
+		card.Interval = 1
+	} else {
+		card.Reps++
+
+		switch card.Reps {
+		case 1:
+			card.Interval = 1
+		case 2:
+			card.Interval = 6
+		default:
+			card.Interval = int(math.Round(float64(card.Interval) * card.Ease))
+		}
+
+		q := float64(quality)
+		card.Ease += 0.1 - (5-q)*(0.08+(5-q)*0.02)
+		if card.Ease < minEase {
+			card.Ease = minEase
+		}
+	}
+
+	now := time.Now()
+	card.LastReview = now
+	card.Due = now.AddDate(0, 0, card.Interval)
+
+	return card
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"testing"
+
+	"note2anki/flashcard"
+)
+
+func TestReviewFirstThreeSuccesses(t *testing.T) {
+	card := &flashcard.Flashcard{}
+
+	Review(card, 5)
+	if card.Reps != 1 || card.Interval != 1 {
+		t.Fatalf("after 1st success: got reps=%d interval=%d, want reps=1 interval=1", card.Reps, card.Interval)
+	}
+	if card.Ease != defaultEase+0.1 {
+		t.Fatalf("after 1st success: got ease=%v, want %v", card.Ease, defaultEase+0.1)
+	}
+
+	Review(card, 5)
+	if card.Reps != 2 || card.Interval != 6 {
+		t.Fatalf("after 2nd success: got reps=%d interval=%d, want reps=2 interval=6", card.Reps, card.Interval)
+	}
+
+	prevInterval, ease := card.Interval, card.Ease
+	Review(card, 5)
+	if card.Reps != 3 {
+		t.Fatalf("after 3rd success: got reps=%d, want 3", card.Reps)
+	}
+	if want := int(float64(prevInterval) * ease); card.Interval < want-1 || card.Interval > want+1 {
+		t.Fatalf("after 3rd success: got interval=%d, want ~%d (prevInterval * ease)", card.Interval, want)
+	}
+}
+
+func TestReviewLapseResetsProgress(t *testing.T) {
+	card := &flashcard.Flashcard{}
+	Review(card, 5)
+	Review(card, 5)
+	if card.Reps == 0 {
+		t.Fatalf("setup: expected reps > 0 before lapse")
+	}
+
+	Review(card, 1)
+	if card.Reps != 0 {
+		t.Fatalf("after lapse: got reps=%d, want 0", card.Reps)
+	}
+	if card.Interval != 1 {
+		t.Fatalf("after lapse: got interval=%d, want 1", card.Interval)
+	}
+}
+
+func TestReviewEaseFloor(t *testing.T) {
+	card := &flashcard.Flashcard{}
+	Review(card, 5) // seed a non-zero ease
+
+	for i := 0; i < 50; i++ {
+		Review(card, 3) // the weakest passing grade, steadily eroding ease
+	}
+
+	if card.Ease < minEase {
+		t.Fatalf("ease fell below floor: got %v, want >= %v", card.Ease, minEase)
+	}
+}
+
+func TestReviewSetsDueFromInterval(t *testing.T) {
+	card := &flashcard.Flashcard{}
+	Review(card, 5)
+
+	gotDays := card.Due.Sub(card.LastReview).Hours() / 24
+	if gotDays < float64(card.Interval)-1 || gotDays > float64(card.Interval)+1 {
+		t.Fatalf("got Due %v days after LastReview, want ~%d", gotDays, card.Interval)
+	}
+}
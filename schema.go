@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// flashcardJSONSchema is the JSON Schema every backend's structured
+// output is constrained to: an array of {front, back, tags?} objects.
+var flashcardJSONSchema = json.RawMessage(`{
+	"type": "array",
+	"items": {
+		"type": "object",
+		"properties": {
+			"front": {"type": "string"},
+			"back": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["front", "back"],
+		"additionalProperties": false
+	}
+}`)
+
+// flashcardGBNF is a GBNF grammar equivalent to flashcardJSONSchema, for
+// llama.cpp/LocalAI-style grammar-constrained decoding.
+const flashcardGBNF = `
+root   ::= "[" ws (card ("," ws card)*)? ws "]"
+card   ::= "{" ws "\"front\"" ws ":" ws string "," ws "\"back\"" ws ":" ws string (ws "," ws "\"tags\"" ws ":" ws tags)? ws "}"
+tags   ::= "[" ws (string ("," ws string)*)? ws "]"
+string ::= "\"" ([^"\\] | "\\" .)* "\""
+ws     ::= [ \t\n]*
+`
+
+// schemaPromptHint is appended to the prompt for backends (like Ollama)
+// whose JSON mode constrains token-level syntax but not field names, so
+// the model still needs to be told the shape.
+const schemaPromptHint = "\n\nRespond with a JSON array where each element has exactly the fields \"front\" (string), \"back\" (string), and optionally \"tags\" (array of strings). No other fields or text."
+
+// SchemaEnforcer picks the right constrained-decoding mechanism for a
+// backend so the model can't emit anything but a valid flashcard array.
+type SchemaEnforcer struct{}
+
+// OpenAIResponseFormat returns the response_format payload for
+// OpenAI-compatible chat-completions backends.
+func (SchemaEnforcer) OpenAIResponseFormat() *openai.ChatCompletionResponseFormat {
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   "flashcards",
+			Schema: flashcardJSONSchema,
+			Strict: true,
+		},
+	}
+}
+
+// Grammar returns the GBNF grammar string for llama.cpp/LocalAI-style
+// backends.
+func (SchemaEnforcer) Grammar() string {
+	return flashcardGBNF
+}
+
+// validateFlashcardSchema checks that data is a JSON array of objects
+// each carrying non-empty "front"/"back" strings and an optional "tags"
+// array, without relying on a full JSON Schema validator.
+func validateFlashcardSchema(data []byte) error {
+	var raw []map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("response is not a JSON array: %w", err)
+	}
+
+	for i, obj := range raw {
+		front, ok := obj["front"].(string)
+		if !ok || strings.TrimSpace(front) == "" {
+			return fmt.Errorf("card %d: missing or empty \"front\" field", i)
+		}
+
+		back, ok := obj["back"].(string)
+		if !ok || strings.TrimSpace(back) == "" {
+			return fmt.Errorf("card %d: missing or empty \"back\" field", i)
+		}
+
+		if tags, present := obj["tags"]; present {
+			if _, ok := tags.([]interface{}); !ok {
+				return fmt.Errorf("card %d: \"tags\" must be an array", i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// strictJSONPassthrough is extractJSON's -strict-json replacement: it
+// trusts that the backend's constrained decoding already guarantees the
+// response is nothing but the JSON array, skips the markdown/prose
+// stripping extractJSON does, and only validates the schema. A mismatch
+// here means the constrained decoding mechanism itself failed, so it is
+// a hard error carrying the raw token stream rather than being papered
+// over by further string trimming.
+func strictJSONPassthrough(response string) (string, error) {
+	trimmed := strings.TrimSpace(response)
+	if err := validateFlashcardSchema([]byte(trimmed)); err != nil {
+		return "", fmt.Errorf("strict JSON validation failed: %w\nRaw model output: %s", err, response)
+	}
+	return trimmed, nil
+}
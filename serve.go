@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"note2anki/parser"
+)
+
+// maxUploadBytes bounds the size of a /v1/convert(/stream) request body.
+// It's generous enough for the documents the bundled parsers target
+// (lecture notes, chapters, slide decks), not arbitrary uploads.
+const maxUploadBytes = 50 << 20 // 50MB
+
+// runServe parses the "serve" subcommand's flags, builds a single shared
+// ProcessingPipeline, and blocks serving the REST API until the process
+// is interrupted.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		configPath string
+		provider   string
+		baseURL    string
+		strictJSON bool
+		workers    int
+		listen     string
+	)
+	fs.StringVar(&configPath, "config", "", "Path to configuration file")
+	fs.StringVar(&provider, "provider", "", "LLM provider: anthropic, openai, ollama, or localai")
+	fs.StringVar(&baseURL, "base-url", "", "Override the provider's API base URL")
+	fs.BoolVar(&strictJSON, "strict-json", false, "Use constrained decoding and hard-fail on any schema mismatch instead of best-effort JSON extraction")
+	fs.IntVar(&workers, "workers", 0, "Concurrent chunk requests in flight per conversion (default: from config, or 4)")
+	fs.StringVar(&listen, "listen", ":8080", "Address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if provider != "" {
+		config.Provider = provider
+	}
+	if baseURL != "" {
+		config.BaseURL = baseURL
+	}
+	if strictJSON {
+		config.StrictJSON = true
+	}
+	if workers > 0 {
+		config.Workers = workers
+	}
+
+	// One pipeline, and so one LLMBackend, shared across every request;
+	// each request only needs its own parsed content and subject.
+	pipeline, err := NewProcessingPipeline(config)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	srv := &apiServer{pipeline: pipeline, token: config.ServerToken}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", srv.handleHealthz)
+	mux.HandleFunc("/v1/formats", srv.handleFormats)
+	mux.HandleFunc("/v1/convert", srv.auth(srv.handleConvert))
+	mux.HandleFunc("/v1/convert/stream", srv.auth(srv.handleConvertStream))
+
+	// Ctrl-C (or SIGTERM) drains in-flight requests instead of dropping
+	// them, mirroring the one-shot CLI's use of signal.NotifyContext to
+	// cancel in-flight chunk requests.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: listen, Handler: mux}
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("🌐 Listening on %s\n", listen)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	// ListenAndServe returns as soon as Shutdown starts; wait for the
+	// drain to finish before returning and letting the process exit.
+	<-shutdownDone
+	return nil
+}
+
+// apiServer holds the state shared across requests: the pipeline built
+// once at startup and the bearer token (if any) required to use it.
+type apiServer struct {
+	pipeline *ProcessingPipeline
+	token    string
+}
+
+// auth wraps next with bearer-token authentication. When token is empty,
+// the API runs unauthenticated, so it should only be exposed behind a
+// trusted network boundary in that case.
+func (s *apiServer) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			next(w, r)
+			return
+		}
+
+		const prefix = "Bearer "
+		got := r.Header.Get("Authorization")
+		if !strings.HasPrefix(got, prefix) || subtle.ConstantTimeCompare([]byte(got[len(prefix):]), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *apiServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// formatsResponse is GET /v1/formats' payload.
+type formatsResponse struct {
+	Input  []string `json:"input"`
+	Output []string `json:"output"`
+}
+
+func (s *apiServer) handleFormats(w http.ResponseWriter, r *http.Request) {
+	resp := formatsResponse{
+		Input:  parser.Default.Extensions(),
+		Output: []string{".txt", ".tsv", ".csv", ".apkg"},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleConvert parses an uploaded file, runs it through the shared
+// pipeline, and returns the resulting flashcards as a JSON array.
+func (s *apiServer) handleConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	content, subject, err := s.extractUpload(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flashcards, err := s.pipeline.ProcessContent(r.Context(), content, subject)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("conversion failed: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flashcards)
+}
+
+// handleConvertStream parses an uploaded file like handleConvert, but
+// emits flashcards as Server-Sent Events as each chunk finishes instead
+// of waiting for the whole document.
+func (s *apiServer) handleConvertStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	content, subject, err := s.extractUpload(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	chunks := chunkText(content, s.pipeline.config.ChunkTokens, s.pipeline.config.ChunkOverlap)
+
+	streamErr := processChunksStream(r.Context(), s.pipeline.llm, chunks, subject, s.pipeline.config.Workers, func(cards []Flashcard) {
+		for i := range cards {
+			cards[i].Tags = append(cards[i].Tags, subject)
+		}
+		data, err := json.Marshal(cards)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	})
+
+	if streamErr != nil {
+		// streamErr's message routinely embeds literal newlines (e.g. a
+		// failed extractJSON error includes the raw multi-paragraph LLM
+		// response), which would break the SSE framing if written raw:
+		// JSON-encode it the same way the success path encodes cards.
+		data, err := json.Marshal(map[string]string{"error": streamErr.Error()})
+		if err != nil {
+			data = []byte(`{"error":"internal error encoding failure message"}`)
+		}
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	} else {
+		fmt.Fprint(w, "event: done\ndata: {}\n\n")
+	}
+	flusher.Flush()
+}
+
+// extractUpload reads the "file" field of a multipart upload, buffers it
+// to a temp file so the parser registry's file-based FileParser
+// implementations can read it, and returns its parsed text content along
+// with the subject derived from the uploaded filename.
+func (s *apiServer) extractUpload(w http.ResponseWriter, r *http.Request) (content, subject string, err error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return "", "", fmt.Errorf("failed to parse upload: %w", err)
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", "", fmt.Errorf(`missing "file" field: %w`, err)
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	fileParser, perr := parser.Default.ParserFor(header.Filename)
+	if perr != nil {
+		if ct := header.Header.Get("Content-Type"); ct != "" {
+			fileParser, perr = parser.Default.ParserForMIME(ct)
+		}
+	}
+	if perr != nil {
+		return "", "", fmt.Errorf("unsupported file format: %s", ext)
+	}
+
+	tmp, err := os.CreateTemp("", "n2a-upload-*"+ext)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to buffer upload: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		return "", "", fmt.Errorf("failed to buffer upload: %w", err)
+	}
+	tmp.Close()
+
+	text, err := fileParser.Parse(tmp.Name())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse upload: %w", err)
+	}
+	if len(strings.TrimSpace(text)) == 0 {
+		return "", "", fmt.Errorf("no text content found in file")
+	}
+
+	subject = strings.TrimSuffix(header.Filename, ext)
+	return text, subject, nil
+}